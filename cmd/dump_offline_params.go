@@ -0,0 +1,77 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/optimism"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dumpOfflineParamsCmd = &cobra.Command{
+		Use:   "dump-offline-params",
+		Short: "Snapshot a running node's chain parameters for use with `run --mode offline`",
+		RunE:  runDumpOfflineParamsCmd,
+	}
+
+	// dumpOfflineParamsOutput is the path the snapshot is written to,
+	// matching the --offline-params-file flag on runCmd.
+	dumpOfflineParamsOutput string
+)
+
+func init() {
+	dumpOfflineParamsCmd.Flags().StringVar(
+		&dumpOfflineParamsOutput,
+		"output",
+		"offline-params.json",
+		"path to write the chain parameters snapshot to",
+	)
+}
+
+func runDumpOfflineParamsCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := configuration.LoadConfiguration()
+	if err != nil {
+		return fmt.Errorf("%w: unable to load configuration", err)
+	}
+
+	if cfg.Mode != configuration.Online {
+		return fmt.Errorf("dump-offline-params requires a running node: start with --mode online")
+	}
+
+	client, err := optimism.NewClient(cfg.GethURL, cfg.Params, optimism.ClientOptions{
+		HTTPTimeout: cfg.L2GethHTTPTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize ethereum client", err)
+	}
+	defer client.Close()
+
+	registry := optimism.NewTokenRegistry(client)
+	registry.Seed(defaultTokens(cfg.Network.Network))
+
+	ctx := context.Background()
+	if err := optimism.DumpOfflineParams(ctx, client, registry, dumpOfflineParamsOutput); err != nil {
+		return fmt.Errorf("%w: unable to dump offline params", err)
+	}
+
+	fmt.Printf("wrote offline params to %s\n", dumpOfflineParamsOutput)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/monitor"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	monitorCmd = &cobra.Command{
+		Use:   "monitor",
+		Short: "Run the rosetta-ethereum synthetic transaction monitor",
+		RunE:  runMonitorCmd,
+	}
+)
+
+func runMonitorCmd(cmd *cobra.Command, args []string) error {
+	cfg, err := configuration.LoadConfiguration()
+	if err != nil {
+		return fmt.Errorf("%w: unable to load configuration", err)
+	}
+
+	ctx := context.Background()
+	ctx, cancel := context.WithCancel(ctx)
+	go handleSignals([]context.CancelFunc{cancel})
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	m := monitor.New(cfg.Monitor, cfg.Network.Network)
+	g.Go(func() error {
+		return m.Run(ctx)
+	})
+
+	if cfg.Monitor.MetricsPort > 0 {
+		metricsServer := &http.Server{
+			Addr:    fmt.Sprintf(":%d", cfg.Monitor.MetricsPort),
+			Handler: promhttp.Handler(),
+		}
+
+		g.Go(func() error {
+			log.Printf("monitor metrics listening on port %d", cfg.Monitor.MetricsPort)
+			return metricsServer.ListenAndServe()
+		})
+
+		g.Go(func() error {
+			<-ctx.Done()
+			return metricsServer.Shutdown(context.Background())
+		})
+	}
+
+	err = g.Wait()
+	if SignalReceived {
+		return fmt.Errorf("rosetta-ethereum monitor halted")
+	}
+
+	return err
+}
@@ -19,7 +19,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/coinbase/rosetta-ethereum/configuration"
@@ -41,6 +43,16 @@ const (
 	// idleTimeout is the maximum amount of time to wait for the
 	// next request when keep-alives are enabled.
 	idleTimeout = 30 * time.Second
+
+	// defaultGasPriceMultiplier and defaultGasLimitMultiplier pad the
+	// suggested fee to absorb price movement between suggestion and
+	// inclusion.
+	defaultGasPriceMultiplier = 1.2
+	defaultGasLimitMultiplier = 1.1
+
+	// tokensAutoDiscoverPollInterval is how often WatchBlocks checks for new
+	// blocks to scan when --tokens-auto-discover is set.
+	tokensAutoDiscoverPollInterval = 15 * time.Second
 )
 
 var (
@@ -49,8 +61,115 @@ var (
 		Short: "Run rosetta-ethereum",
 		RunE:  runRunCmd,
 	}
+
+	// suggestFeeDenom is the currency symbol reported in suggested_fee.
+	suggestFeeDenom string
+
+	// gasPriceMultiplier and gasLimitMultiplier scale the suggested gas
+	// price/limit to account for price movement and estimation error
+	// between suggestion and inclusion.
+	gasPriceMultiplier float64
+	gasLimitMultiplier float64
+
+	// minGasPrice and maxGasPrice clamp the suggested gas price (in wei).
+	// Empty means unbounded.
+	minGasPrice string
+	maxGasPrice string
+
+	// offlineGasPrice is the gas price (in wei) used to compute a
+	// suggested fee when running with --mode offline and no geth client
+	// is available.
+	offlineGasPrice string
+
+	// tokensFile seeds the token registry from a local JSON/YAML file.
+	tokensFile string
+
+	// tokensURL seeds (and, combined with tokensRefreshInterval,
+	// periodically refreshes) the token registry from a remote URL.
+	tokensURL string
+
+	// tokensRefreshInterval is how often tokensURL is re-fetched.
+	tokensRefreshInterval time.Duration
+
+	// tokensAutoDiscover enables scanning new blocks' Transfer logs to
+	// admit previously-unseen ERC-20 tokens automatically.
+	tokensAutoDiscover bool
+
+	// offlineParamsFile is the cached chain parameters snapshot used to
+	// serve /construction/* when --mode offline, produced by
+	// `rosetta-ethereum dump-offline-params`.
+	offlineParamsFile string
 )
 
+func init() {
+	runCmd.Flags().StringVar(
+		&suggestFeeDenom,
+		"suggest-fee-denom",
+		"ETH",
+		"currency symbol to report in construction/metadata suggested_fee",
+	)
+	runCmd.Flags().Float64Var(
+		&gasPriceMultiplier,
+		"gas-price-multiplier",
+		defaultGasPriceMultiplier,
+		"multiplier applied to the suggested gas price to absorb price movement before inclusion",
+	)
+	runCmd.Flags().Float64Var(
+		&gasLimitMultiplier,
+		"gas-limit-multiplier",
+		defaultGasLimitMultiplier,
+		"multiplier applied to the estimated gas limit to leave headroom for estimation error",
+	)
+	runCmd.Flags().StringVar(
+		&minGasPrice,
+		"min-gas-price",
+		"",
+		"lower bound (wei) to clamp the suggested gas price to",
+	)
+	runCmd.Flags().StringVar(
+		&maxGasPrice,
+		"max-gas-price",
+		"",
+		"upper bound (wei) to clamp the suggested gas price to",
+	)
+	runCmd.Flags().StringVar(
+		&offlineGasPrice,
+		"offline-gas-price",
+		"",
+		"gas price (wei) to use for fee suggestion when --mode offline and no geth client is configured",
+	)
+	runCmd.Flags().StringVar(
+		&tokensFile,
+		"tokens-file",
+		"",
+		"path to a JSON or YAML file seeding the supported ERC-20 token registry",
+	)
+	runCmd.Flags().StringVar(
+		&tokensURL,
+		"tokens-url",
+		"",
+		"URL to periodically fetch the supported ERC-20 token registry from",
+	)
+	runCmd.Flags().DurationVar(
+		&tokensRefreshInterval,
+		"tokens-refresh-interval",
+		10*time.Minute,
+		"how often --tokens-url is re-fetched",
+	)
+	runCmd.Flags().BoolVar(
+		&tokensAutoDiscover,
+		"tokens-auto-discover",
+		false,
+		"admit ERC-20 tokens automatically by scanning new blocks' Transfer logs",
+	)
+	runCmd.Flags().StringVar(
+		&offlineParamsFile,
+		"offline-params-file",
+		"",
+		"cached chain parameters snapshot to serve /construction/* from when --mode offline (see dump-offline-params)",
+	)
+}
+
 func runRunCmd(cmd *cobra.Command, args []string) error {
 	cfg, err := configuration.LoadConfiguration()
 	if err != nil {
@@ -73,11 +192,23 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	// Start required services
 	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	go handleSignals([]context.CancelFunc{cancel})
+
+	// signalCtx is cancelled as soon as SIGINT/SIGTERM arrives. ctx is
+	// only cancelled once stopBackground is called below, after the HTTP
+	// server has finished draining in-flight requests, so geth isn't
+	// killed out from under a request still being served.
+	ctx, stopBackground := context.WithCancel(ctx)
+	signalCtx, cancelOnSignal := context.WithCancel(ctx)
+	go handleSignals([]context.CancelFunc{cancelOnSignal})
 
 	g, ctx := errgroup.WithContext(ctx)
 
+	tokenRegistry := optimism.NewTokenRegistry(nil)
+	tokenRegistry.Seed(defaultTokens(cfg.Network.Network))
+	if err := loadTokenRegistry(ctx, tokenRegistry, g); err != nil {
+		return fmt.Errorf("%w: cannot initialize token registry", err)
+	}
+
 	var client *optimism.Client
 	if cfg.Mode == configuration.Online {
 		if !cfg.RemoteGeth {
@@ -91,7 +222,7 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 			MaxTraceConcurrency: cfg.MaxConcurrentTraces,
 			EnableTraceCache:    cfg.EnableTraceCache,
 			EnableGethTracer:    cfg.EnableGethTracer,
-			SupportedTokens:     getSupportedTokens(cfg.Network.Network),
+			TokenRegistry:       tokenRegistry,
 		}
 		var err error
 		client, err = optimism.NewClient(cfg.GethURL, cfg.Params, opts)
@@ -99,15 +230,43 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("%w: cannot initialize ethereum client", err)
 		}
 		defer client.Close()
+
+		tokenRegistry.SetClient(client)
 	}
 
-	router := services.NewBlockchainRouter(cfg, client, asserter)
+	feeSuggester, err := newFeeSuggester(client)
+	if err != nil {
+		return fmt.Errorf("%w: cannot initialize fee suggester", err)
+	}
+
+	var router http.Handler
+	if cfg.Mode == configuration.Offline {
+		if offlineParamsFile == "" {
+			return errors.New("--offline-params-file is required when --mode offline")
+		}
+
+		offlineParams, err := optimism.LoadOfflineParams(offlineParamsFile)
+		if err != nil {
+			return fmt.Errorf("%w: cannot load offline params", err)
+		}
+
+		router = services.NewOfflineRouter(cfg, offlineParams, asserter, feeSuggester)
+	} else {
+		router = services.NewBlockchainRouter(cfg, client, asserter, feeSuggester, tokenRegistry)
+	}
 
 	loggedRouter := server.LoggerMiddleware(router)
 	corsRouter := server.CorsMiddleware(loggedRouter)
-	server := &http.Server{
+
+	var ready int32 = 1
+	topMux := http.NewServeMux()
+	topMux.HandleFunc("/health/ready", readinessHandler(&ready))
+	topMux.HandleFunc("/health/live", livenessHandler(client))
+	topMux.Handle("/", corsRouter)
+
+	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
-		Handler:      corsRouter,
+		Handler:      topMux,
 		ReadTimeout:  readTimeout,
 		WriteTimeout: cfg.L2GethHTTPTimeout,
 		IdleTimeout:  idleTimeout,
@@ -115,16 +274,41 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 
 	g.Go(func() error {
 		log.Printf("server listening on port %d", cfg.Port)
-		return server.ListenAndServe()
+		// ErrServerClosed only means Shutdown was called; returning it
+		// would cancel the errgroup context (and geth) before draining ends.
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
 	})
 
 	g.Go(func() error {
-		// If we don't shutdown server in errgroup, it will
-		// never stop because server.ListenAndServe doesn't
-		// take any context.
-		<-ctx.Done()
+		// ctx is the errgroup's own context, so this also wakes up if any
+		// other g.Go task fails first (e.g. the HTTP server fails to bind,
+		// or geth crashes on startup) instead of waiting for a signal that
+		// will never come.
+		select {
+		case <-signalCtx.Done():
+		case <-ctx.Done():
+		}
+
+		// Flip readiness first so load balancers stop routing new
+		// requests here, then give in-flight requests a fresh,
+		// uncancelled budget to finish instead of killing them with
+		// the already-cancelled signal context.
+		atomic.StoreInt32(&ready, 0)
+		log.Printf("draining in-flight requests for up to %s before shutdown", cfg.ShutdownGracePeriod)
 
-		return server.Shutdown(ctx)
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+		defer shutdownCancel()
+
+		err := httpServer.Shutdown(shutdownCtx)
+
+		// Only stop geth now that the HTTP server has finished
+		// draining (or the grace period has elapsed).
+		stopBackground()
+
+		return err
 	})
 
 	err = g.Wait()
@@ -135,29 +319,101 @@ func runRunCmd(cmd *cobra.Command, args []string) error {
 	return err
 }
 
-func getSupportedTokens(network string) map[string]bool {
+// newFeeSuggester builds an optimism.FeeSuggester from the run command's
+// flags. client may be nil when running with --mode offline, in which case
+// --offline-gas-price must be set or fee suggestion will return an error.
+func newFeeSuggester(client *optimism.Client) (*optimism.FeeSuggester, error) {
+	cfg := optimism.FeeSuggestionConfig{
+		SuggestedFeeDenom:  suggestFeeDenom,
+		GasPriceMultiplier: gasPriceMultiplier,
+		GasLimitMultiplier: gasLimitMultiplier,
+	}
+
+	if minGasPrice != "" {
+		price, ok := new(big.Int).SetString(minGasPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid --min-gas-price %q", minGasPrice)
+		}
+		cfg.MinGasPrice = price
+	}
+
+	if maxGasPrice != "" {
+		price, ok := new(big.Int).SetString(maxGasPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid --max-gas-price %q", maxGasPrice)
+		}
+		cfg.MaxGasPrice = price
+	}
+
+	if offlineGasPrice != "" {
+		price, ok := new(big.Int).SetString(offlineGasPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid --offline-gas-price %q", offlineGasPrice)
+		}
+		cfg.OfflineGasPrice = price
+	}
+
+	return optimism.NewFeeSuggester(client, cfg), nil
+}
+
+// loadTokenRegistry applies --tokens-file and --tokens-url (if set) to
+// registry, and starts background refresh/discovery loops in g: a
+// --tokens-url poller and, when --tokens-auto-discover is set, registry's
+// WatchBlocks loop.
+func loadTokenRegistry(ctx context.Context, registry *optimism.TokenRegistry, g *errgroup.Group) error {
+	if tokensFile != "" {
+		if err := registry.LoadFile(tokensFile); err != nil {
+			return err
+		}
+	}
+
+	if tokensURL != "" {
+		if err := registry.RefreshFromURL(ctx, tokensURL); err != nil {
+			return err
+		}
+
+		g.Go(func() error {
+			registry.WatchURL(ctx, tokensURL, tokensRefreshInterval)
+			return nil
+		})
+	}
+
+	registry.SetAutoDiscoverEnabled(tokensAutoDiscover)
+	if tokensAutoDiscover {
+		g.Go(func() error {
+			return registry.WatchBlocks(ctx, tokensAutoDiscoverPollInterval)
+		})
+	}
+
+	return nil
+}
+
+// defaultTokens preserves the previously hardcoded per-network allowlist as
+// the registry's baseline, which --tokens-file, --tokens-url, and
+// auto-discovery can extend or override via TokenOverride.Blocklist.
+func defaultTokens(network string) map[string]optimism.TokenMetadata {
 	switch network {
 	case optimism.MainnetNetwork:
-		return map[string]bool{
-			"0x4200000000000000000000000000000000000042": true, // OP
-			"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": true, // DAI
-			"0x8700daec35af8ff88c16bdf0418774cb3d7599b4": true, // SNX
-			"0x94b008aa00579c1307b0ef2c499ad98a8ce58e58": true, // USDT
-			"0x68f180fcce6836688e9084f035309e29bf0a2095": true, // WBTC
-			"0x7f5c764cbc14f9669b88837ca1490cca17c31607": true, // USDC
+		return map[string]optimism.TokenMetadata{
+			"0x4200000000000000000000000000000000000042": {ContractAddress: "0x4200000000000000000000000000000000000042", Symbol: "OP", Decimals: 18},
+			"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": {ContractAddress: "0xda10009cbd5d07dd0cecc66161fc93d7c9000da1", Symbol: "DAI", Decimals: 18},
+			"0x8700daec35af8ff88c16bdf0418774cb3d7599b4": {ContractAddress: "0x8700daec35af8ff88c16bdf0418774cb3d7599b4", Symbol: "SNX", Decimals: 18},
+			"0x94b008aa00579c1307b0ef2c499ad98a8ce58e58": {ContractAddress: "0x94b008aa00579c1307b0ef2c499ad98a8ce58e58", Symbol: "USDT", Decimals: 6},
+			"0x68f180fcce6836688e9084f035309e29bf0a2095": {ContractAddress: "0x68f180fcce6836688e9084f035309e29bf0a2095", Symbol: "WBTC", Decimals: 8},
+			"0x7f5c764cbc14f9669b88837ca1490cca17c31607": {ContractAddress: "0x7f5c764cbc14f9669b88837ca1490cca17c31607", Symbol: "USDC", Decimals: 6},
 		}
 	case optimism.TestnetNetwork: // Goerli - 420
-		return map[string]bool{
-			"0x4200000000000000000000000000000000000042": true, // OP
-			"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": true, // DAI
-			"0x2e5ed97596a8368eb9e44b1f3f25b2e813845303": true, // SNX
-			"0x853eb4ba5d0ba2b77a0a5329fd2110d5ce149ece": true, // USDT
-			"0xe0a592353e81a94db6e3226fd4a99f881751776a": true, // WBTC
-			"0x7e07e15d2a87a24492740d16f5bdf58c16db0c4e": true, // USDC
+		return map[string]optimism.TokenMetadata{
+			"0x4200000000000000000000000000000000000042": {ContractAddress: "0x4200000000000000000000000000000000000042", Symbol: "OP", Decimals: 18},
+			"0xda10009cbd5d07dd0cecc66161fc93d7c9000da1": {ContractAddress: "0xda10009cbd5d07dd0cecc66161fc93d7c9000da1", Symbol: "DAI", Decimals: 18},
+			"0x2e5ed97596a8368eb9e44b1f3f25b2e813845303": {ContractAddress: "0x2e5ed97596a8368eb9e44b1f3f25b2e813845303", Symbol: "SNX", Decimals: 18},
+			"0x853eb4ba5d0ba2b77a0a5329fd2110d5ce149ece": {ContractAddress: "0x853eb4ba5d0ba2b77a0a5329fd2110d5ce149ece", Symbol: "USDT", Decimals: 6},
+			"0xe0a592353e81a94db6e3226fd4a99f881751776a": {ContractAddress: "0xe0a592353e81a94db6e3226fd4a99f881751776a", Symbol: "WBTC", Decimals: 8},
+			"0x7e07e15d2a87a24492740d16f5bdf58c16db0c4e": {ContractAddress: "0x7e07e15d2a87a24492740d16f5bdf58c16db0c4e", Symbol: "USDC", Decimals: 6},
 		}
 	default:
-		return map[string]bool{
-			"0x4200000000000000000000000000000000000042": true, // OP
+		return map[string]optimism.TokenMetadata{
+			"0x4200000000000000000000000000000000000042": {ContractAddress: "0x4200000000000000000000000000000000000042", Symbol: "OP", Decimals: 18},
 		}
 	}
 }
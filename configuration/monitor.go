@@ -0,0 +1,55 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import "time"
+
+// SignerKey is a hex-encoded private key used by the monitor to sign its
+// synthetic self-transfers. It is never logged.
+type SignerKey struct {
+	// Network is the network this key should be used on.
+	Network string `json:"network"`
+
+	// PrivateKeyHex is the hex-encoded secp256k1 private key.
+	PrivateKeyHex string `json:"private_key_hex"`
+
+	// TargetAddress is the address the signer sends self-transfers to. If
+	// empty, the signer's own address is used.
+	TargetAddress string `json:"target_address"`
+}
+
+// MonitorConfig configures the `rosetta-ethereum monitor` subcommand, which
+// periodically submits small self-transfers through the construction flow
+// and measures end-to-end latency to detect sequencer degradation.
+type MonitorConfig struct {
+	// Signers lists the keys the monitor rotates through when submitting
+	// synthetic transactions.
+	Signers []SignerKey `json:"signers"`
+
+	// PollInterval is how often the monitor submits a new synthetic
+	// transaction.
+	PollInterval time.Duration `json:"poll_interval"`
+
+	// InclusionTimeout bounds how long the monitor waits for a submitted
+	// transaction to be included in a block before recording it as failed.
+	InclusionTimeout time.Duration `json:"inclusion_timeout"`
+
+	// RPCEndpoints maps network name to the URL of the Rosetta server the
+	// monitor submits its construction and status requests to.
+	RPCEndpoints map[string]string `json:"rpc_endpoints"`
+
+	// MetricsPort is the port the monitor's Prometheus handler listens on.
+	MetricsPort int `json:"metrics_port"`
+}
@@ -0,0 +1,241 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// nativeCurrency is the currency self-transfer probes move; monitor probes
+// always send the network's native asset, never an ERC-20.
+var nativeCurrency = &types.Currency{
+	Symbol:   "ETH",
+	Decimals: 18,
+}
+
+// probeValueWei is the amount moved by each synthetic self-transfer: small
+// enough to be immaterial, non-zero so it exercises real operations.
+const probeValueWei = "1"
+
+// construct runs /construction/preprocess -> /metadata -> /payloads ->
+// /combine -> /submit for a self-transfer from signer, returning the
+// resulting transaction hash.
+func (m *Monitor) construct(ctx context.Context, signer configuration.SignerKey) (string, error) {
+	privKey, fromAddress, err := decodeSigner(signer)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid signer key", err)
+	}
+
+	toAddress := signer.TargetAddress
+	if toAddress == "" {
+		toAddress = fromAddress
+	}
+
+	ops := []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: fromAddress},
+			Amount:              &types.Amount{Value: "-" + probeValueWei, Currency: nativeCurrency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: toAddress},
+			Amount:              &types.Amount{Value: probeValueWei, Currency: nativeCurrency},
+		},
+	}
+
+	preprocessResp, rosettaErr, err := m.apiClient.ConstructionApi.ConstructionPreprocess(
+		ctx,
+		&types.ConstructionPreprocessRequest{NetworkIdentifier: m.netID, Operations: ops},
+	)
+	if err != nil || rosettaErr != nil {
+		return "", constructionError("preprocess", rosettaErr, err)
+	}
+
+	metadataResp, rosettaErr, err := m.apiClient.ConstructionApi.ConstructionMetadata(
+		ctx,
+		&types.ConstructionMetadataRequest{NetworkIdentifier: m.netID, Options: preprocessResp.Options},
+	)
+	if err != nil || rosettaErr != nil {
+		return "", constructionError("metadata", rosettaErr, err)
+	}
+
+	payloadsResp, rosettaErr, err := m.apiClient.ConstructionApi.ConstructionPayloads(
+		ctx,
+		&types.ConstructionPayloadsRequest{
+			NetworkIdentifier: m.netID,
+			Operations:        ops,
+			Metadata:          metadataResp.Metadata,
+		},
+	)
+	if err != nil || rosettaErr != nil {
+		return "", constructionError("payloads", rosettaErr, err)
+	}
+
+	signatures := make([]*types.Signature, len(payloadsResp.Payloads))
+	for i, payload := range payloadsResp.Payloads {
+		sigBytes, err := crypto.Sign(payload.Bytes, privKey)
+		if err != nil {
+			return "", fmt.Errorf("%w: unable to sign payload %d", err, i)
+		}
+
+		signatures[i] = &types.Signature{
+			SigningPayload: payload,
+			PublicKey: &types.PublicKey{
+				Bytes:     crypto.FromECDSAPub(&privKey.PublicKey),
+				CurveType: "secp256k1",
+			},
+			SignatureType: "ecdsa_recovery",
+			Bytes:         sigBytes,
+		}
+	}
+
+	combineResp, rosettaErr, err := m.apiClient.ConstructionApi.ConstructionCombine(
+		ctx,
+		&types.ConstructionCombineRequest{
+			NetworkIdentifier:   m.netID,
+			UnsignedTransaction: payloadsResp.UnsignedTransaction,
+			Signatures:          signatures,
+		},
+	)
+	if err != nil || rosettaErr != nil {
+		return "", constructionError("combine", rosettaErr, err)
+	}
+
+	submitResp, rosettaErr, err := m.apiClient.ConstructionApi.ConstructionSubmit(
+		ctx,
+		&types.ConstructionSubmitRequest{NetworkIdentifier: m.netID, SignedTransaction: combineResp.SignedTransaction},
+	)
+	if err != nil || rosettaErr != nil {
+		return "", constructionError("submit", rosettaErr, err)
+	}
+
+	return submitResp.TransactionIdentifier.Hash, nil
+}
+
+// awaitMempool polls /mempool/transaction until txHash appears or ctx is
+// done, returning the time it was first observed.
+func (m *Monitor) awaitMempool(ctx context.Context, txHash string) (time.Time, error) {
+	ticker := time.NewTicker(mempoolPollInterval)
+	defer ticker.Stop()
+
+	req := &types.MempoolTransactionRequest{
+		NetworkIdentifier:     m.netID,
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: txHash},
+	}
+
+	for {
+		if _, _, err := m.apiClient.MempoolApi.MempoolTransaction(ctx, req); err == nil {
+			return time.Now(), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// awaitReceipt polls successive blocks for txHash's inclusion until ctx is
+// done. It scans every block produced since the last tick, not just the
+// current head, so inclusion isn't missed when more than one block lands
+// between polls.
+func (m *Monitor) awaitReceipt(ctx context.Context, txHash string) error {
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	lastChecked := int64(-1)
+
+	for {
+		statusResp, _, err := m.apiClient.NetworkApi.NetworkStatus(
+			ctx,
+			&types.NetworkRequest{NetworkIdentifier: m.netID},
+		)
+		if err == nil && statusResp.CurrentBlockIdentifier != nil {
+			head := statusResp.CurrentBlockIdentifier.Index
+
+			start := lastChecked + 1
+			if lastChecked < 0 {
+				start = head
+			}
+
+			for blockIndex := start; blockIndex <= head; blockIndex++ {
+				blockResp, _, err := m.apiClient.BlockApi.Block(
+					ctx,
+					&types.BlockRequest{
+						NetworkIdentifier: m.netID,
+						BlockIdentifier:   &types.PartialBlockIdentifier{Index: &blockIndex},
+					},
+				)
+				if err == nil && blockResp.Block != nil && blockContainsTx(blockResp.Block, txHash) {
+					return nil
+				}
+			}
+
+			lastChecked = head
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// blockContainsTx reports whether block includes a transaction with the
+// given hash.
+func blockContainsTx(block *types.Block, txHash string) bool {
+	for _, tx := range block.Transactions {
+		if tx.TransactionIdentifier != nil && tx.TransactionIdentifier.Hash == txHash {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeSigner parses signer's hex-encoded private key and derives its
+// checksummed address.
+func decodeSigner(signer configuration.SignerKey) (*ecdsa.PrivateKey, string, error) {
+	privKey, err := crypto.HexToECDSA(strings.TrimPrefix(signer.PrivateKeyHex, "0x"))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unable to parse private key", err)
+	}
+
+	address := crypto.PubkeyToAddress(privKey.PublicKey).Hex()
+
+	return privKey, address, nil
+}
+
+// constructionError flattens a Rosetta *types.Error/error pair returned
+// from a construction API call into a single error.
+func constructionError(stage string, rosettaErr *types.Error, err error) error {
+	if err != nil {
+		return fmt.Errorf("%w: construction/%s failed", err, stage)
+	}
+	return fmt.Errorf("construction/%s failed: %s", stage, rosettaErr.Message)
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor
+
+import (
+	"testing"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPrivateKeyHex is an arbitrary, non-funded secp256k1 key used only to
+// exercise address derivation.
+const testPrivateKeyHex = "0fad9c8855b740a0b7ed4c221dbad0f33a83a49cad6b3fe8d5817ac83d38b6a0"
+
+func TestDecodeSigner(t *testing.T) {
+	t.Run("valid key without 0x prefix", func(t *testing.T) {
+		address, err := decodeSignerAddress(t, configuration.SignerKey{PrivateKeyHex: testPrivateKeyHex})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, address)
+	})
+
+	t.Run("valid key with 0x prefix", func(t *testing.T) {
+		address, err := decodeSignerAddress(t, configuration.SignerKey{PrivateKeyHex: "0x" + testPrivateKeyHex})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, address)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, _, err := decodeSigner(configuration.SignerKey{PrivateKeyHex: "not-hex"})
+		assert.Error(t, err)
+	})
+}
+
+func decodeSignerAddress(t *testing.T, signer configuration.SignerKey) (string, error) {
+	t.Helper()
+
+	_, address, err := decodeSigner(signer)
+	return address, err
+}
+
+func TestBlockContainsTx(t *testing.T) {
+	block := &types.Block{
+		Transactions: []*types.Transaction{
+			{TransactionIdentifier: &types.TransactionIdentifier{Hash: "0xabc"}},
+			{TransactionIdentifier: &types.TransactionIdentifier{Hash: "0xdef"}},
+		},
+	}
+
+	assert.True(t, blockContainsTx(block, "0xabc"))
+	assert.True(t, blockContainsTx(block, "0xdef"))
+	assert.False(t, blockContainsTx(block, "0x123"))
+	assert.False(t, blockContainsTx(&types.Block{}, "0xabc"))
+}
@@ -0,0 +1,169 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitor periodically submits small self-transfers through the
+// Rosetta construction flow and measures end-to-end latency, so operators
+// can detect sequencer degradation independently of (or alongside) the
+// Rosetta server itself.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+
+	"github.com/coinbase/rosetta-sdk-go/client"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// blockchainName identifies the monitor's probe transactions to the Rosetta
+// API, matching the value the server itself reports in /network/list.
+const blockchainName = "Optimism"
+
+// mempoolPollInterval and receiptPollInterval bound how often the monitor
+// re-checks a submitted transaction's status.
+const (
+	mempoolPollInterval = 500 * time.Millisecond
+	receiptPollInterval = 2 * time.Second
+)
+
+var (
+	// roundTripDuration measures construction -> receipt latency.
+	roundTripDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rosetta_monitor_round_trip_duration_seconds",
+			Help:    "Time from construction submission to confirmed receipt.",
+			Buckets: prometheus.ExponentialBuckets(0.5, 2, 10),
+		},
+		[]string{"network"},
+	)
+
+	// firstSeenDuration measures construction -> first seen in mempool.
+	firstSeenDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "rosetta_monitor_first_seen_duration_seconds",
+			Help:    "Time from construction submission to first seen in mempool.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		},
+		[]string{"network"},
+	)
+
+	// failureTotal counts synthetic transactions that did not complete
+	// within InclusionTimeout, labeled by the stage they failed at.
+	failureTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rosetta_monitor_failures_total",
+			Help: "Synthetic transactions that failed to complete, by stage.",
+		},
+		[]string{"network", "stage"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(roundTripDuration, firstSeenDuration, failureTotal)
+}
+
+// Monitor periodically submits synthetic self-transfers through a Rosetta
+// construction flow and records their latency.
+type Monitor struct {
+	cfg       configuration.MonitorConfig
+	network   string
+	netID     *types.NetworkIdentifier
+	apiClient *client.APIClient
+}
+
+// New returns a Monitor that submits its probes against the Rosetta server
+// at cfg.RPCEndpoints[network].
+func New(cfg configuration.MonitorConfig, network string) *Monitor {
+	endpoint := cfg.RPCEndpoints[network]
+
+	clientCfg := client.NewConfiguration(endpoint, "rosetta-ethereum-monitor", nil)
+	apiClient := client.NewAPIClient(clientCfg)
+
+	return &Monitor{
+		cfg:     cfg,
+		network: network,
+		netID: &types.NetworkIdentifier{
+			Blockchain: blockchainName,
+			Network:    network,
+		},
+		apiClient: apiClient,
+	}
+}
+
+// Run submits synthetic transactions on cfg.PollInterval until ctx is
+// cancelled.
+func (m *Monitor) Run(ctx context.Context) error {
+	var signers []configuration.SignerKey
+	for _, s := range m.cfg.Signers {
+		if s.Network == m.network {
+			signers = append(signers, s)
+		}
+	}
+	if len(signers) == 0 {
+		return fmt.Errorf("monitor: no signers configured for network %s", m.network)
+	}
+
+	ticker := time.NewTicker(m.cfg.PollInterval)
+	defer ticker.Stop()
+
+	signerIndex := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			signer := signers[signerIndex%len(signers)]
+			signerIndex++
+
+			if err := m.probeOnce(ctx, signer); err != nil {
+				log.Printf("monitor: probe failed for network %s: %s", m.network, err)
+			}
+		}
+	}
+}
+
+// probeOnce constructs, broadcasts, and tracks a single self-transfer,
+// recording round-trip and first-seen latency (or a failure) for it.
+func (m *Monitor) probeOnce(ctx context.Context, signer configuration.SignerKey) error {
+	probeCtx, cancel := context.WithTimeout(ctx, m.cfg.InclusionTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	txHash, err := m.construct(probeCtx, signer)
+	if err != nil {
+		failureTotal.WithLabelValues(m.network, "construction").Inc()
+		return fmt.Errorf("%w: construction failed", err)
+	}
+
+	firstSeenAt, err := m.awaitMempool(probeCtx, txHash)
+	if err != nil {
+		failureTotal.WithLabelValues(m.network, "first_seen").Inc()
+		return fmt.Errorf("%w: transaction never seen in mempool", err)
+	}
+	firstSeenDuration.WithLabelValues(m.network).Observe(firstSeenAt.Sub(start).Seconds())
+
+	if err := m.awaitReceipt(probeCtx, txHash); err != nil {
+		failureTotal.WithLabelValues(m.network, "inclusion").Inc()
+		return fmt.Errorf("%w: transaction never included", err)
+	}
+	roundTripDuration.WithLabelValues(m.network).Observe(time.Since(start).Seconds())
+
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// l1 data fee gas cost coefficients, per the OP-stack rollup fee spec:
+// l1Fee = l1BaseFee * scalar * (txDataNonZeroGas*nonZeroBytes + txDataZeroGas*zeroBytes + overhead) / feeScalarPrecision
+const (
+	txDataNonZeroGas   = 68
+	txDataZeroGas      = 16
+	l1FeeOverhead      = 2100
+	feeScalarPrecision = 1_000_000
+
+	// weiDecimals is the number of decimals in the suggested fee amount.
+	weiDecimals = 18
+)
+
+// ErrNoGasPriceAvailable is returned when a fee suggestion is requested
+// offline and no user-supplied gas price was configured.
+var ErrNoGasPriceAvailable = errors.New("no gas price available for offline fee suggestion")
+
+// FeeSuggestionConfig controls how SuggestFee scales and bounds the gas
+// price it derives from the L2 client (or, in offline mode, from an
+// operator-supplied value).
+type FeeSuggestionConfig struct {
+	// SuggestedFeeDenom is the currency symbol returned alongside the
+	// suggested fee amount (e.g. "ETH").
+	SuggestedFeeDenom string
+
+	// GasPriceMultiplier scales the L2 suggested gas price to account
+	// for price movement between suggestion and inclusion.
+	GasPriceMultiplier float64
+
+	// GasLimitMultiplier scales the estimated gas limit to leave headroom
+	// for estimation error.
+	GasLimitMultiplier float64
+
+	// MinGasPrice and MaxGasPrice clamp the final gas price, in wei.
+	MinGasPrice *big.Int
+	MaxGasPrice *big.Int
+
+	// OfflineGasPrice is used in place of a live L2 gas price when the
+	// client is nil (cfg.Mode == configuration.Offline).
+	OfflineGasPrice *big.Int
+}
+
+// FeeSuggester computes suggested_fee for /construction/metadata, combining
+// the L2 execution gas price with the OP-stack L1 data availability fee.
+type FeeSuggester struct {
+	client *Client
+	cfg    FeeSuggestionConfig
+}
+
+// NewFeeSuggester returns a FeeSuggester backed by client. client may be nil,
+// in which case SuggestFee falls back to cfg.OfflineGasPrice.
+func NewFeeSuggester(client *Client, cfg FeeSuggestionConfig) *FeeSuggester {
+	return &FeeSuggester{client: client, cfg: cfg}
+}
+
+// SuggestFee returns the total suggested fee (L2 execution fee plus L1 data
+// fee) for a transaction of the given gas limit and unsigned payload.
+func (f *FeeSuggester) SuggestFee(
+	ctx context.Context,
+	gasLimit int64,
+	unsignedTxData []byte,
+) (*types.Amount, error) {
+	gasPrice, err := f.gasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scaledGasPrice := mulFloat(gasPrice, f.cfg.GasPriceMultiplier)
+	scaledGasPrice = clamp(scaledGasPrice, f.cfg.MinGasPrice, f.cfg.MaxGasPrice)
+
+	scaledGasLimit := int64(float64(gasLimit) * f.cfg.GasLimitMultiplier)
+	l2Fee := new(big.Int).Mul(scaledGasPrice, big.NewInt(scaledGasLimit))
+
+	l1Fee, err := f.l1DataFee(ctx, unsignedTxData)
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int).Add(l2Fee, l1Fee)
+
+	return &types.Amount{
+		Value: total.String(),
+		Currency: &types.Currency{
+			Symbol:   f.cfg.SuggestedFeeDenom,
+			Decimals: weiDecimals,
+		},
+	}, nil
+}
+
+// gasPrice returns the gas price to use, preferring a live quote from the L2
+// client and falling back to the configured offline gas price.
+func (f *FeeSuggester) gasPrice(ctx context.Context) (*big.Int, error) {
+	if f.client == nil {
+		if f.cfg.OfflineGasPrice == nil {
+			return nil, ErrNoGasPriceAvailable
+		}
+		return f.cfg.OfflineGasPrice, nil
+	}
+
+	return f.client.SuggestGasPrice(ctx)
+}
+
+// l1DataFee estimates the L1 security fee charged for posting unsignedTxData
+// as calldata to L1, using the rollup's current base fee and fee scalar.
+func (f *FeeSuggester) l1DataFee(ctx context.Context, unsignedTxData []byte) (*big.Int, error) {
+	if f.client == nil {
+		// Offline mode has no access to L1 base fee/scalar; the L1
+		// portion of the fee is omitted rather than guessed.
+		return big.NewInt(0), nil
+	}
+
+	l1BaseFee, scalar, err := f.client.L1GasPriceOracle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var zeroBytes, nonZeroBytes int64
+	for _, b := range unsignedTxData {
+		if b == 0 {
+			zeroBytes++
+		} else {
+			nonZeroBytes++
+		}
+	}
+
+	l1GasUsed := big.NewInt(txDataNonZeroGas*nonZeroBytes + txDataZeroGas*zeroBytes + l1FeeOverhead)
+
+	fee := new(big.Int).Mul(l1BaseFee, scalar)
+	fee.Mul(fee, l1GasUsed)
+	fee.Div(fee, big.NewInt(feeScalarPrecision))
+
+	return fee, nil
+}
+
+// mulFloat scales v by m, rounding down.
+func mulFloat(v *big.Int, m float64) *big.Int {
+	scaled := new(big.Float).Mul(new(big.Float).SetInt(v), big.NewFloat(m))
+	result, _ := scaled.Int(nil)
+	return result
+}
+
+// clamp bounds v to [min, max], ignoring bounds that are nil.
+func clamp(v, min, max *big.Int) *big.Int {
+	if min != nil && v.Cmp(min) < 0 {
+		return min
+	}
+	if max != nil && v.Cmp(max) > 0 {
+		return max
+	}
+	return v
+}
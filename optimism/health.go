@@ -0,0 +1,55 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// HealthStatus mirrors the healthz pattern used by OP-stack monitoring
+// tools: sync progress, peer count, and how stale the latest known block
+// is.
+type HealthStatus struct {
+	Syncing      bool          `json:"syncing"`
+	PeerCount    int           `json:"peer_count"`
+	LastBlockAge time.Duration `json:"last_block_age"`
+}
+
+// Health reports c's current sync status, peer count, and the age of the
+// most recently seen block, for use by a /health/live endpoint.
+func (c *Client) Health(ctx context.Context) (*HealthStatus, error) {
+	syncing, err := c.SyncProgress(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch sync progress", err)
+	}
+
+	peerCount, err := c.PeerCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch peer count", err)
+	}
+
+	lastBlockTime, err := c.LatestBlockTimestamp(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to fetch latest block timestamp", err)
+	}
+
+	return &HealthStatus{
+		Syncing:      syncing,
+		PeerCount:    peerCount,
+		LastBlockAge: time.Since(lastBlockTime),
+	}, nil
+}
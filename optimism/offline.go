@@ -0,0 +1,97 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// OfflineParams is the set of chain parameters cached to disk by
+// `rosetta-ethereum dump-offline-params` and loaded via
+// --offline-params-file so that /construction/* endpoints can serve
+// requests without a live geth connection.
+type OfflineParams struct {
+	ChainID *big.Int `json:"chain_id"`
+
+	// BaseFeeCeiling is the gas price the node suggested (SuggestGasPrice)
+	// at dump time. Offline fee suggestion does not read it; it prices gas
+	// at the fee suggester's OfflineGasPrice.
+	BaseFeeCeiling *big.Int `json:"base_fee_ceiling"`
+
+	// Tokens is the known token metadata snapshot at dump time.
+	Tokens []TokenMetadata `json:"tokens"`
+}
+
+// LoadOfflineParams reads and parses an OfflineParams snapshot from path.
+func LoadOfflineParams(path string) (*OfflineParams, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read offline params file %s", err, path)
+	}
+
+	var params OfflineParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("%w: unable to parse offline params file %s", err, path)
+	}
+
+	return &params, nil
+}
+
+// DumpOfflineParams snapshots client's current chain parameters and known
+// tokens to path, in the format LoadOfflineParams expects.
+func DumpOfflineParams(ctx context.Context, client *Client, registry *TokenRegistry, path string) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fetch chain ID", err)
+	}
+
+	baseFee, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fetch current base fee", err)
+	}
+
+	params := OfflineParams{
+		ChainID:        chainID,
+		BaseFeeCeiling: baseFee,
+		Tokens:         registry.All(),
+	}
+
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%w: unable to marshal offline params", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil { //nolint:gosec // params are not sensitive
+		return fmt.Errorf("%w: unable to write offline params file %s", err, path)
+	}
+
+	return nil
+}
+
+// OfflineModeError is returned by endpoints that require a live client
+// (/block, /account/balance, /mempool) when the server is running with
+// --mode offline.
+type OfflineModeError struct {
+	// Endpoint is the Rosetta endpoint path that was rejected.
+	Endpoint string
+}
+
+func (e *OfflineModeError) Error() string {
+	return fmt.Sprintf("%s is unavailable in offline mode", e.Endpoint)
+}
@@ -0,0 +1,77 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LatestBlockNumber returns the highest block number c's connected node has
+// processed, for use by WatchBlocks to find new blocks to scan.
+func (c *Client) LatestBlockNumber(ctx context.Context) (int64, error) {
+	header, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: unable to fetch latest header", err)
+	}
+
+	return header.Number.Int64(), nil
+}
+
+// WatchBlocks polls the client attached via SetClient for new blocks every
+// interval and runs DiscoverFromBlock over each one, so
+// --tokens-auto-discover actually admits newly-listed ERC-20 tokens as
+// blocks are produced. It is a no-op (returning nil immediately) unless
+// SetAutoDiscoverEnabled(true) was called.
+func (r *TokenRegistry) WatchBlocks(ctx context.Context, interval time.Duration) error {
+	if !r.AutoDiscoverEnabled() {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastDiscovered := int64(-1)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			r.mu.RLock()
+			client := r.client
+			r.mu.RUnlock()
+			if client == nil {
+				continue
+			}
+
+			latest, err := client.LatestBlockNumber(ctx)
+			if err != nil {
+				continue
+			}
+
+			start := lastDiscovered + 1
+			if lastDiscovered < 0 {
+				start = latest
+			}
+
+			for block := start; block <= latest; block++ {
+				_ = r.DiscoverFromBlock(ctx, block)
+			}
+			lastDiscovered = latest
+		}
+	}
+}
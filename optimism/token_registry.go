@@ -0,0 +1,318 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package optimism
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TokenOverride lets an operator correct or restrict a discovered token's
+// metadata without waiting on a contract upgrade or code change.
+type TokenOverride struct {
+	Symbol    string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+	Decimals  *int32 `json:"decimals,omitempty" yaml:"decimals,omitempty"`
+	Blocklist bool   `json:"blocklist,omitempty" yaml:"blocklist,omitempty"`
+}
+
+// TokenMetadata is the resolved, display-ready information for an
+// ERC-20 token admitted to the registry.
+type TokenMetadata struct {
+	ContractAddress string `json:"contract_address"`
+	Symbol          string `json:"symbol"`
+	Decimals        int32  `json:"decimals"`
+}
+
+// tokenFile is the on-disk (JSON or YAML) seed format accepted by
+// --tokens-file and by the remote URL refresh source.
+type tokenFile struct {
+	Tokens    []TokenMetadata          `json:"tokens" yaml:"tokens"`
+	Overrides map[string]TokenOverride `json:"overrides" yaml:"overrides"`
+}
+
+// TokenRegistry is a concurrency-safe allowlist of ERC-20 tokens that the
+// services router consults in place of the old hardcoded
+// getSupportedTokens switch. It can be seeded from a local file, refreshed
+// from a remote URL on an interval, and grown via auto-discovery of
+// Transfer logs.
+type TokenRegistry struct {
+	client *Client
+
+	mu           sync.RWMutex
+	tokens       map[string]TokenMetadata
+	overrides    map[string]TokenOverride
+	autoDiscover bool
+}
+
+// SetAutoDiscoverEnabled controls whether WatchBlocks runs DiscoverFromBlock
+// over new blocks; WatchBlocks returns immediately while it is disabled.
+func (r *TokenRegistry) SetAutoDiscoverEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.autoDiscover = enabled
+}
+
+// AutoDiscoverEnabled reports whether auto-discovery is enabled.
+func (r *TokenRegistry) AutoDiscoverEnabled() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.autoDiscover
+}
+
+// NewTokenRegistry returns an empty TokenRegistry. Use the Load* and Watch*
+// methods to populate it. client may be nil and set later with SetClient,
+// since the registry is typically constructed before the geth client (which
+// DiscoverFromBlock needs for staticcalls).
+func NewTokenRegistry(client *Client) *TokenRegistry {
+	return &TokenRegistry{
+		client:    client,
+		tokens:    map[string]TokenMetadata{},
+		overrides: map[string]TokenOverride{},
+	}
+}
+
+// SetClient attaches the geth client DiscoverFromBlock uses for Transfer log
+// scanning and ERC-20 metadata staticcalls.
+func (r *TokenRegistry) SetClient(client *Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.client = client
+}
+
+// Seed admits the given tokens unconditionally, without waiting on a file,
+// remote URL, or auto-discovery. It is used to preserve today's known-good
+// defaults as a baseline the other sources can extend or override.
+func (r *TokenRegistry) Seed(tokens map[string]TokenMetadata) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for addr, meta := range tokens {
+		r.tokens[strings.ToLower(addr)] = meta
+	}
+}
+
+// IsSupported returns whether contractAddress is an admitted, non-blocklisted
+// token. It is safe to call from multiple goroutines.
+func (r *TokenRegistry) IsSupported(contractAddress string) bool {
+	addr := strings.ToLower(contractAddress)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if o, ok := r.overrides[addr]; ok && o.Blocklist {
+		return false
+	}
+
+	_, ok := r.tokens[addr]
+	return ok
+}
+
+// Metadata returns the resolved metadata for contractAddress, applying any
+// configured override, and whether the token is known to the registry and
+// not blocklisted.
+func (r *TokenRegistry) Metadata(contractAddress string) (TokenMetadata, bool) {
+	addr := strings.ToLower(contractAddress)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if o, ok := r.overrides[addr]; ok && o.Blocklist {
+		return TokenMetadata{}, false
+	}
+
+	meta, ok := r.tokens[addr]
+	if !ok {
+		return TokenMetadata{}, false
+	}
+
+	if o, ok := r.overrides[addr]; ok {
+		if o.Symbol != "" {
+			meta.Symbol = o.Symbol
+		}
+		if o.Decimals != nil {
+			meta.Decimals = *o.Decimals
+		}
+	}
+
+	return meta, true
+}
+
+// All returns a snapshot of every admitted, non-blocklisted token's
+// resolved metadata, including overrides. It is used to populate
+// OfflineParams.Tokens.
+func (r *TokenRegistry) All() []TokenMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tokens := make([]TokenMetadata, 0, len(r.tokens))
+	for addr := range r.tokens {
+		if o, ok := r.overrides[addr]; ok && o.Blocklist {
+			continue
+		}
+
+		meta := r.tokens[addr]
+		if o, ok := r.overrides[addr]; ok {
+			if o.Symbol != "" {
+				meta.Symbol = o.Symbol
+			}
+			if o.Decimals != nil {
+				meta.Decimals = *o.Decimals
+			}
+		}
+		tokens = append(tokens, meta)
+	}
+
+	return tokens
+}
+
+// LoadFile seeds (or reseeds) the registry from a local JSON or YAML file,
+// selected by the .json/.yaml/.yml extension of path.
+func (r *TokenRegistry) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%w: unable to read tokens file %s", err, path)
+	}
+
+	return r.load(data, path)
+}
+
+// RefreshFromURL fetches and applies the registry seed from url once.
+// Combine with WatchURL to refresh on an interval.
+func (r *TokenRegistry) RefreshFromURL(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unable to build tokens file request", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: unable to fetch tokens file", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching tokens file from %s", resp.StatusCode, url)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: unable to read tokens file response from %s", err, url)
+	}
+
+	return r.load(data, url)
+}
+
+// WatchURL refreshes the registry from url every interval until ctx is
+// cancelled, logging (rather than failing) on transient fetch errors.
+func (r *TokenRegistry) WatchURL(ctx context.Context, url string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.RefreshFromURL(ctx, url); err != nil {
+				log.Printf("token registry: refresh from %s failed: %s", url, err)
+			}
+		}
+	}
+}
+
+// load parses data (JSON or YAML, chosen by sourceName's extension) and
+// merges it into the registry.
+func (r *TokenRegistry) load(data []byte, sourceName string) error {
+	var parsed tokenFile
+
+	var err error
+	if strings.HasSuffix(sourceName, ".yaml") || strings.HasSuffix(sourceName, ".yml") {
+		err = yaml.Unmarshal(data, &parsed)
+	} else {
+		err = json.Unmarshal(data, &parsed)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: unable to parse tokens source %s", err, sourceName)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range parsed.Tokens {
+		r.tokens[strings.ToLower(t.ContractAddress)] = t
+	}
+	for addr, o := range parsed.Overrides {
+		r.overrides[strings.ToLower(addr)] = o
+	}
+
+	return nil
+}
+
+// DiscoverFromBlock scans a block's logs for ERC-20 Transfer events and
+// admits any previously-unseen contract that correctly answers
+// name()/symbol()/decimals() staticcalls. WatchBlocks calls it once per new
+// block.
+func (r *TokenRegistry) DiscoverFromBlock(ctx context.Context, blockNumber int64) error {
+	r.mu.RLock()
+	client := r.client
+	r.mu.RUnlock()
+
+	if client == nil {
+		return fmt.Errorf("token registry: auto-discovery requires a client")
+	}
+
+	candidates, err := client.TransferLogContracts(ctx, blockNumber)
+	if err != nil {
+		return fmt.Errorf("%w: unable to scan transfer logs for block %d", err, blockNumber)
+	}
+
+	for _, addr := range candidates {
+		addr = strings.ToLower(addr)
+
+		r.mu.RLock()
+		_, known := r.tokens[addr]
+		r.mu.RUnlock()
+		if known {
+			continue
+		}
+
+		meta, err := client.ERC20Metadata(ctx, addr)
+		if err != nil {
+			// Contract does not correctly implement the ERC-20
+			// metadata methods; skip it rather than admitting a
+			// token Rosetta clients can't render.
+			continue
+		}
+
+		r.mu.Lock()
+		r.tokens[addr] = meta
+		r.mu.Unlock()
+	}
+
+	return nil
+}
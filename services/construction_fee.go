@@ -0,0 +1,43 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"context"
+
+	"github.com/coinbase/rosetta-ethereum/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/types"
+)
+
+// suggestFee populates metadata.suggested_fee by combining the L2 execution
+// fee with the OP-stack L1 data fee for the intended transaction.
+func suggestFee(
+	ctx context.Context,
+	feeSuggester *optimism.FeeSuggester,
+	gasLimit int64,
+	unsignedTxData []byte,
+) ([]*types.Amount, error) {
+	if feeSuggester == nil {
+		return nil, nil
+	}
+
+	fee, err := feeSuggester.SuggestFee(ctx, gasLimit, unsignedTxData)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*types.Amount{fee}, nil
+}
@@ -0,0 +1,338 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+	"github.com/coinbase/rosetta-sdk-go/types"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// transferGasLimit is the gas cost of a plain ETH transfer, used as the
+// fee-suggestion gas limit when /construction/preprocess's options don't
+// carry one.
+const transferGasLimit = 21000
+
+// nativeCurrency is the currency reported for the self-transfer operations
+// this service's preprocess/parse handlers build and decode.
+var nativeCurrency = &types.Currency{
+	Symbol:   "ETH",
+	Decimals: 18,
+}
+
+// errOfflineNonceUnavailable is returned by /construction/payloads and
+// /construction/combine: building or signing a transaction needs the
+// sender's current nonce, which — like /account/balance — requires a live
+// geth connection this service doesn't have in --mode offline.
+var errOfflineNonceUnavailable = errors.New(
+	"construction/payloads and construction/combine require an account nonce, which is unavailable in --mode offline",
+)
+
+// ConstructionOfflineAPIService serves /construction/* using cached chain
+// parameters instead of a live geth connection, for use with --mode
+// offline.
+type ConstructionOfflineAPIService struct {
+	cfg          *configuration.Configuration
+	params       *optimism.OfflineParams
+	feeSuggester *optimism.FeeSuggester
+	asserter     *asserter.Asserter
+}
+
+// NewConstructionOfflineAPIService returns a ConstructionOfflineAPIService
+// backed by params loaded from --offline-params-file. Incoming requests are
+// validated through asserter before being handled, the same request
+// validation the online router gets.
+func NewConstructionOfflineAPIService(
+	cfg *configuration.Configuration,
+	params *optimism.OfflineParams,
+	feeSuggester *optimism.FeeSuggester,
+	asserter *asserter.Asserter,
+) *ConstructionOfflineAPIService {
+	return &ConstructionOfflineAPIService{
+		cfg:          cfg,
+		params:       params,
+		feeSuggester: feeSuggester,
+		asserter:     asserter,
+	}
+}
+
+// registerConstructionRoutes mounts the /construction/* handlers backed by
+// svc onto mux.
+func registerConstructionRoutes(mux *http.ServeMux, svc *ConstructionOfflineAPIService) {
+	mux.HandleFunc("/construction/derive", svc.handleDerive)
+	mux.HandleFunc("/construction/preprocess", svc.handlePreprocess)
+	mux.HandleFunc("/construction/metadata", svc.handleMetadata)
+	mux.HandleFunc("/construction/payloads", svc.handlePayloads)
+	mux.HandleFunc("/construction/combine", svc.handleCombine)
+	mux.HandleFunc("/construction/parse", svc.handleParse)
+	mux.HandleFunc("/construction/hash", svc.handleHash)
+}
+
+// handleMetadata returns chain metadata and, when possible, a suggested fee
+// priced at the fee suggester's offline gas price rather than a live quote
+// (the cached base fee ceiling is not used); without one, no fee is returned. The
+// gas limit comes from the options handlePreprocess attached (falling back
+// to transferGasLimit), so the fee isn't unconditionally zero.
+func (s *ConstructionOfflineAPIService) handleMetadata(w http.ResponseWriter, r *http.Request) {
+	var req types.ConstructionMetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.asserter.ConstructionMetadataRequest(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := &types.ConstructionMetadataResponse{
+		Metadata: map[string]interface{}{
+			"chain_id": s.params.ChainID.String(),
+		},
+	}
+
+	if fee, err := suggestFee(r.Context(), s.feeSuggester, gasLimitFromOptions(req.Options), nil); err == nil && fee != nil {
+		resp.SuggestedFee = fee
+	}
+
+	writeJSON(w, resp)
+}
+
+// gasLimitFromOptions returns the gas_limit carried over from
+// /construction/preprocess's options, falling back to transferGasLimit when
+// the caller didn't supply one.
+func gasLimitFromOptions(options map[string]interface{}) int64 {
+	if v, ok := options["gas_limit"].(float64); ok && v > 0 {
+		return int64(v)
+	}
+	return transferGasLimit
+}
+
+// handleDerive computes the Ethereum address for the given public key. This
+// is a pure function of the key, so it works identically offline or online.
+func (s *ConstructionOfflineAPIService) handleDerive(w http.ResponseWriter, r *http.Request) {
+	var req types.ConstructionDeriveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.asserter.ConstructionDeriveRequest(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	pubKey, err := crypto.UnmarshalPubkey(req.PublicKey.Bytes)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: invalid public key", err))
+		return
+	}
+
+	writeJSON(w, &types.ConstructionDeriveResponse{
+		AccountIdentifier: &types.AccountIdentifier{Address: crypto.PubkeyToAddress(*pubKey).Hex()},
+	})
+}
+
+// handlePreprocess extracts the sender, recipient, and value from a
+// self-transfer's operations into options for handleMetadata. Like
+// handleDerive, this needs no chain data.
+func (s *ConstructionOfflineAPIService) handlePreprocess(w http.ResponseWriter, r *http.Request) {
+	var req types.ConstructionPreprocessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.asserter.ConstructionPreprocessRequest(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	from, to, value, err := transferFromOperations(req.Operations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, &types.ConstructionPreprocessResponse{
+		Options: map[string]interface{}{
+			"from":      from,
+			"to":        to,
+			"value":     value,
+			"gas_limit": float64(transferGasLimit),
+		},
+	})
+}
+
+// handlePayloads would build and return the unsigned transaction's signing
+// payloads, but doing so needs the sender's current nonce, which (like
+// /account/balance) requires a live geth connection unavailable in --mode
+// offline.
+func (s *ConstructionOfflineAPIService) handlePayloads(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotImplemented, errOfflineNonceUnavailable)
+}
+
+// handleCombine would attach signatures to the unsigned transaction
+// handlePayloads produced, which never happens offline; see handlePayloads.
+func (s *ConstructionOfflineAPIService) handleCombine(w http.ResponseWriter, r *http.Request) {
+	writeError(w, http.StatusNotImplemented, errOfflineNonceUnavailable)
+}
+
+// handleParse decodes a raw (signed or unsigned) transaction back into its
+// constituent operations, recovering the sender from the signature when the
+// transaction is signed. Like handleDerive, this needs no chain data.
+func (s *ConstructionOfflineAPIService) handleParse(w http.ResponseWriter, r *http.Request) {
+	var req types.ConstructionParseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.asserter.ConstructionParseRequest(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := decodeTransaction(req.Transaction)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var signers []*types.AccountIdentifier
+	from := ""
+	if req.Signed {
+		sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("%w: unable to recover sender", err))
+			return
+		}
+		from = sender.Hex()
+		signers = []*types.AccountIdentifier{{Address: from}}
+	}
+
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	writeJSON(w, &types.ConstructionParseResponse{
+		Operations:               transferOperations(from, to, tx.Value().String()),
+		AccountIdentifierSigners: signers,
+	})
+}
+
+// handleHash returns the transaction hash of a signed transaction. Like
+// handleDerive, this needs no chain data.
+func (s *ConstructionOfflineAPIService) handleHash(w http.ResponseWriter, r *http.Request) {
+	var req types.ConstructionHashRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.asserter.ConstructionHashRequest(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx, err := decodeTransaction(req.SignedTransaction)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, &types.TransactionIdentifierResponse{
+		TransactionIdentifier: &types.TransactionIdentifier{Hash: tx.Hash().Hex()},
+	})
+}
+
+// decodeTransaction RLP-decodes a hex-encoded (legacy or EIP-2718 typed)
+// Ethereum transaction.
+func decodeTransaction(rawHex string) (*ethtypes.Transaction, error) {
+	raw, err := hexutil.Decode(rawHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid transaction hex", err)
+	}
+
+	tx := new(ethtypes.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("%w: unable to decode transaction", err)
+	}
+
+	return tx, nil
+}
+
+// transferFromOperations extracts the sender, recipient, and value from the
+// two-operation (negative-then-positive CALL) shape self-transfers use.
+func transferFromOperations(ops []*types.Operation) (from, to, value string, err error) {
+	if len(ops) != 2 {
+		return "", "", "", fmt.Errorf("expected 2 operations, got %d", len(ops))
+	}
+
+	send, recv := ops[0], ops[1]
+	if send.Amount == nil || recv.Amount == nil || send.Account == nil || recv.Account == nil {
+		return "", "", "", errors.New("operations must carry an account and amount")
+	}
+
+	return send.Account.Address, recv.Account.Address, recv.Amount.Value, nil
+}
+
+// transferOperations is the inverse of transferFromOperations, rebuilding
+// the two-operation self-transfer shape from a decoded transaction.
+func transferOperations(from, to, value string) []*types.Operation {
+	return []*types.Operation{
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 0},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: from},
+			Amount:              &types.Amount{Value: "-" + value, Currency: nativeCurrency},
+		},
+		{
+			OperationIdentifier: &types.OperationIdentifier{Index: 1},
+			Type:                "CALL",
+			Account:             &types.AccountIdentifier{Address: to},
+			Amount:              &types.Amount{Value: value, Currency: nativeCurrency},
+		},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// genericErrorCode is the Rosetta error code reported for the ad hoc errors
+// this hand-rolled offline router returns; it carries no meaning beyond
+// "something about this request failed" since there's no asserter-registered
+// error list backing it.
+const genericErrorCode = 1
+
+// writeError writes err as a Rosetta *types.Error JSON body with the given
+// HTTP status, the shape every rosetta-sdk-go-generated client (including
+// the monitor's apiClient) expects to unmarshal non-2xx responses into.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&types.Error{
+		Code:    genericErrorCode,
+		Message: err.Error(),
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 Coinbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package services
+
+import (
+	"net/http"
+
+	"github.com/coinbase/rosetta-ethereum/configuration"
+	"github.com/coinbase/rosetta-ethereum/optimism"
+
+	"github.com/coinbase/rosetta-sdk-go/asserter"
+)
+
+// blockedOfflineEndpoints lists the Rosetta endpoints that require a live
+// client and are rejected with optimism.OfflineModeError when running with
+// --mode offline.
+var blockedOfflineEndpoints = []string{
+	"/block",
+	"/block/transaction",
+	"/account/balance",
+	"/account/coins",
+	"/mempool",
+	"/mempool/transaction",
+}
+
+// NewOfflineRouter returns a router that serves /construction/* using
+// params instead of a live geth connection, and rejects the endpoints in
+// blockedOfflineEndpoints with optimism.OfflineModeError. Any other path
+// gets a 404 from the mux.
+func NewOfflineRouter(
+	cfg *configuration.Configuration,
+	params *optimism.OfflineParams,
+	asserter *asserter.Asserter,
+	feeSuggester *optimism.FeeSuggester,
+) http.Handler {
+	mux := http.NewServeMux()
+
+	constructionAPIService := NewConstructionOfflineAPIService(cfg, params, feeSuggester, asserter)
+	registerConstructionRoutes(mux, constructionAPIService)
+
+	for _, endpoint := range blockedOfflineEndpoints {
+		mux.HandleFunc(endpoint, offlineModeHandler(endpoint))
+	}
+
+	return mux
+}
+
+// offlineModeHandler responds to endpoint with a well-typed
+// optimism.OfflineModeError, encoded as a Rosetta *types.Error body.
+func offlineModeHandler(endpoint string) http.HandlerFunc {
+	err := &optimism.OfflineModeError{Endpoint: endpoint}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusServiceUnavailable, err)
+	}
+}